@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// ingestCSV reads a header row followed by records from r, dispatching
+// each record to the template named by its "action" column and building
+// that template's replacement data from the columns it declares in
+// config (Template.Fields).
+func ingestCSV(d *dispatch.Dispatcher, cfg *config.Config, r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	actionCol := columnIndex(header, "action")
+	emailCol := columnIndex(header, "email")
+	if actionCol < 0 || emailCol < 0 {
+		return fmt.Errorf(`CSV header must include "action" and "email" columns`)
+	}
+
+	destinations := make(map[string][]sender.Destination)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			logrus.WithError(err).Error("failed to read CSV record")
+			continue
+		}
+
+		if actionCol >= len(record) || emailCol >= len(record) {
+			logrus.WithField("record", record).Warn("short CSV record, skipping")
+			continue
+		}
+
+		action := record[actionCol]
+		tmpl, ok := cfg.Templates[action]
+		if !ok {
+			logrus.WithField("action", action).Warn("unknown action, skipping record")
+			continue
+		}
+
+		fieldData := make(map[string]string, len(tmpl.Fields))
+		for _, field := range tmpl.Fields {
+			if col := columnIndex(header, field); col >= 0 && col < len(record) {
+				fieldData[field] = record[col]
+			}
+		}
+
+		if err := tmpl.ValidateData(fieldData); err != nil {
+			logrus.WithError(err).Warn("skipping record that fails schema validation")
+			continue
+		}
+
+		encoded, err := json.Marshal(fieldData)
+		if err != nil {
+			return fmt.Errorf("failed to encode replacement data: %w", err)
+		}
+
+		destinations[action] = append(destinations[action], sender.Destination{Email: record[emailCol], Data: string(encoded)})
+	}
+
+	for action, dests := range destinations {
+		sendInBatches(d, cfg.Templates[action], dests)
+	}
+
+	return nil
+}
+
+// sendInBatches splits dests into chunks of at most dispatch.MaxBatchSize
+// before calling d.Send, since SES caps SendBulkTemplatedEmail at that many
+// destinations per call.
+func sendInBatches(d *dispatch.Dispatcher, tmpl config.Template, dests []sender.Destination) {
+	for len(dests) > 0 {
+		n := dispatch.MaxBatchSize
+		if n > len(dests) {
+			n = len(dests)
+		}
+		d.Send(context.Background(), tmpl, dests[:n])
+		dests = dests[n:]
+	}
+}
+
+func columnIndex(header []string, name string) int {
+	for i, col := range header {
+		if col == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// jsonlRecord is one line of -input=jsonl STDIN input.
+type jsonlRecord struct {
+	Template string            `json:"template"`
+	To       string            `json:"to"`
+	Data     map[string]string `json:"data"`
+}
+
+// ingestJSONL reads one jsonlRecord per line from r and dispatches it to
+// the named template.
+func ingestJSONL(d *dispatch.Dispatcher, cfg *config.Config, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	destinations := make(map[string][]sender.Destination)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			logrus.WithError(err).Error("failed to parse JSON-Lines record")
+			continue
+		}
+
+		tmpl, ok := cfg.Templates[rec.Template]
+		if !ok {
+			logrus.WithField("template", rec.Template).Warn("unknown template, skipping record")
+			continue
+		}
+
+		if err := tmpl.ValidateData(rec.Data); err != nil {
+			logrus.WithError(err).Warn("skipping record that fails schema validation")
+			continue
+		}
+
+		encoded, err := json.Marshal(rec.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode replacement data: %w", err)
+		}
+
+		destinations[rec.Template] = append(destinations[rec.Template], sender.Destination{Email: rec.To, Data: string(encoded)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read JSON-Lines input: %w", err)
+	}
+
+	for name, dests := range destinations {
+		sendInBatches(d, cfg.Templates[name], dests)
+	}
+
+	return nil
+}
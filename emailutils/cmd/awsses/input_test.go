@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	"github.com/tetsuo/mailroom/emailutils/retry"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// fakeSender records every batch it's asked to Send, for assertions on how
+// ingestCSV/ingestJSONL chunked and routed destinations.
+type fakeSender struct {
+	mu      sync.Mutex
+	batches [][]sender.Destination
+}
+
+func (f *fakeSender) Send(ctx context.Context, tmpl config.Template, destinations []sender.Destination) ([]sender.Result, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, append([]sender.Destination(nil), destinations...))
+	f.mu.Unlock()
+
+	results := make([]sender.Result, len(destinations))
+	for i, d := range destinations {
+		results[i] = sender.Result{Email: d.Email, Status: "Success"}
+	}
+	return results, nil
+}
+
+func (f *fakeSender) SendRendered(ctx context.Context, from string, messages []sender.RenderedMessage) ([]sender.Result, error) {
+	return nil, nil
+}
+
+func (f *fakeSender) batchSizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sizes := make([]int, len(f.batches))
+	for i, b := range f.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func newTestDispatcher(t *testing.T) (*dispatch.Dispatcher, *fakeSender) {
+	t.Helper()
+
+	q, err := retry.Open(filepath.Join(t.TempDir(), "retry.db"), 5, time.Minute)
+	if err != nil {
+		t.Fatalf("retry.Open() error = %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+
+	fs := &fakeSender{}
+	return dispatch.New(fs, q), fs
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Provider: config.ProviderSES,
+		Templates: map[string]config.Template{
+			"activation": {Name: "activation", From: "noreply@example.com", Fields: []string{"login"}},
+		},
+	}
+}
+
+func TestIngestCSVSkipsShortRecords(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	csv := "action,email,login\n" +
+		"activation,a@example.com,ana\n" +
+		"activation\n" // too short: missing email and login columns
+
+	if err := ingestCSV(d, testConfig(), strings.NewReader(csv)); err != nil {
+		t.Fatalf("ingestCSV() error = %v", err)
+	}
+
+	if sizes := fs.batchSizes(); len(sizes) != 1 || sizes[0] != 1 {
+		t.Fatalf("batchSizes() = %v, want [1] (the short record must be skipped, not panic)", sizes)
+	}
+}
+
+func TestIngestCSVSkipsUnknownActionAndInvalidSchema(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	csv := "action,email,login\n" +
+		"unknown,a@example.com,ana\n" + // unknown action
+		"activation,b@example.com,\n" + // fails ValidateData: login required
+		"activation,c@example.com,carla\n"
+
+	if err := ingestCSV(d, testConfig(), strings.NewReader(csv)); err != nil {
+		t.Fatalf("ingestCSV() error = %v", err)
+	}
+
+	sizes := fs.batchSizes()
+	if len(sizes) != 1 || sizes[0] != 1 {
+		t.Fatalf("batchSizes() = %v, want [1] (only the valid record)", sizes)
+	}
+}
+
+func TestIngestCSVChunksLargeBatches(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+
+	var csv strings.Builder
+	csv.WriteString("action,email,login\n")
+	for i := 0; i < dispatch.MaxBatchSize+5; i++ {
+		csv.WriteString("activation,user@example.com,ana\n")
+	}
+
+	if err := ingestCSV(d, testConfig(), strings.NewReader(csv.String())); err != nil {
+		t.Fatalf("ingestCSV() error = %v", err)
+	}
+
+	sizes := fs.batchSizes()
+	total := 0
+	for _, n := range sizes {
+		if n > dispatch.MaxBatchSize {
+			t.Fatalf("batch size %d exceeds dispatch.MaxBatchSize %d", n, dispatch.MaxBatchSize)
+		}
+		total += n
+	}
+	if total != dispatch.MaxBatchSize+5 {
+		t.Fatalf("total destinations sent = %d, want %d", total, dispatch.MaxBatchSize+5)
+	}
+}
+
+func TestIngestJSONLChunksLargeBatches(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+
+	var jsonl strings.Builder
+	for i := 0; i < dispatch.MaxBatchSize+1; i++ {
+		jsonl.WriteString(`{"template":"activation","to":"user@example.com","data":{"login":"ana"}}` + "\n")
+	}
+
+	if err := ingestJSONL(d, testConfig(), strings.NewReader(jsonl.String())); err != nil {
+		t.Fatalf("ingestJSONL() error = %v", err)
+	}
+
+	sizes := fs.batchSizes()
+	for _, n := range sizes {
+		if n > dispatch.MaxBatchSize {
+			t.Fatalf("batch size %d exceeds dispatch.MaxBatchSize %d", n, dispatch.MaxBatchSize)
+		}
+	}
+	if len(sizes) != 2 {
+		t.Fatalf("batchSizes() = %v, want 2 batches for %d destinations", sizes, dispatch.MaxBatchSize+1)
+	}
+}
+
+func TestIngestJSONLSkipsInvalidLines(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	jsonl := `not json` + "\n" +
+		`{"template":"unknown","to":"a@example.com","data":{}}` + "\n" +
+		`{"template":"activation","to":"b@example.com","data":{"login":"bea"}}` + "\n"
+
+	if err := ingestJSONL(d, testConfig(), strings.NewReader(jsonl)); err != nil {
+		t.Fatalf("ingestJSONL() error = %v", err)
+	}
+
+	if sizes := fs.batchSizes(); len(sizes) != 1 || sizes[0] != 1 {
+		t.Fatalf("batchSizes() = %v, want [1]", sizes)
+	}
+}
@@ -1,151 +1,99 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"flag"
-	"fmt"
 	"os"
-	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ses"
-	"github.com/aws/aws-sdk-go-v2/service/ses/types"
-)
-
-type awsSES interface {
-	SendBulkTemplatedEmail(ctx context.Context, params *ses.SendBulkTemplatedEmailInput, optFns ...func(*ses.Options)) (*ses.SendBulkTemplatedEmailOutput, error)
-}
+	"github.com/sirupsen/logrus"
 
-const (
-	fieldsPerRecord           = 5
-	fromEmailActivation       = "Mercury <noreply@example.com>"
-	templateActivation        = "ActivationEmail"
-	configSetActivation       = "default"
-	fromEmailPasswordRecovery = "Mercury <noreply@example.com>"
-	templatePasswordRecovery  = "PasswordRecoveryEmail"
-	configSetPasswordRecovery = "default"
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	"github.com/tetsuo/mailroom/emailutils/retry"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+	"github.com/tetsuo/mailroom/emailutils/server"
 )
 
-var devMode = flag.Bool("debug", false, "enable fake SES client")
+var (
+	configPath   = flag.String("config", "mailroom.toml", "path to the mailroom TOML config")
+	devMode      = flag.Bool("debug", false, "enable fake SES client")
+	retryDBPath  = flag.String("retry-db", "mailroom-retry.db", "path to the retry queue's BoltDB file")
+	maxAttempts  = flag.Int("max-attempts", 5, "maximum retry attempts before a destination is dead-lettered")
+	retryBackoff = flag.Duration("retry-backoff", 30*time.Second, "base backoff between retry attempts")
+	serve        = flag.Bool("serve", false, "run in server mode, ingesting over HTTP/gRPC instead of STDIN")
+	httpAddr     = flag.String("http-addr", ":8080", "address for the HTTP API, /healthz and /metrics (server mode)")
+	grpcAddr     = flag.String("grpc-addr", ":9090", "address for the gRPC API (server mode)")
+	inputFormat  = flag.String("input", "csv", "STDIN input format: csv (header row + an action column) or jsonl")
+)
 
 func main() {
 	flag.Parse()
 
-	var c awsSES
-	if !*devMode {
-		awscfg, err := awsconfig.LoadDefaultConfig(context.Background())
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "[FATAL] failed to load AWS default config: %v\n", err)
-			os.Exit(1)
-		}
-		c = ses.NewFromConfig(awscfg)
-	} else {
-		fmt.Fprint(os.Stderr, "[INFO] debug mode enabled; mock SES client is being used\n")
-		c = &mockSESClient{}
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		logrus.WithError(err).Fatalf("failed to read config %s", *configPath)
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
+	cfg, err := config.Load(data)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to load config")
+	}
 
-		destinationsActivation, destinationsPasswordRecovery, err := parseDestinations(line)
+	var s sender.Sender
+	if *devMode {
+		logrus.Info("debug mode enabled; mock SES client is being used")
+		s = sender.NewSESWithClient(&mockSESClient{})
+	} else {
+		s, err = sender.New(context.Background(), cfg)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
-			continue
-		}
-
-		if len(destinationsActivation) > 0 {
-			sendBulkEmails(c, fromEmailActivation, templateActivation, configSetActivation, destinationsActivation, "{\"login\":\"\",\"secret\":\"\"}")
-		}
-
-		if len(destinationsPasswordRecovery) > 0 {
-			sendBulkEmails(c, fromEmailPasswordRecovery, templatePasswordRecovery, configSetPasswordRecovery, destinationsPasswordRecovery, "{\"login\":\"\",\"secret\":\"\",\"code\":\"\"}")
+			logrus.WithError(err).Fatal("failed to build sender")
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to read from STDIN: %v\n", err)
+	queue, err := retry.Open(*retryDBPath, *maxAttempts, *retryBackoff)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to open retry queue")
 	}
-}
+	defer queue.Close()
 
-func parseDestinations(line string) ([]types.BulkEmailDestination, []types.BulkEmailDestination, error) {
-	fields := strings.Split(line, ",")
-	if len(fields)%fieldsPerRecord != 0 {
-		return nil, nil, fmt.Errorf("invalid input format; line doesn't align with %d fields per record", fieldsPerRecord)
-	}
+	d := dispatch.New(s, queue)
+	defer d.Shutdown()
 
-	var destinationsActivation []types.BulkEmailDestination
-	var destinationsPasswordRecovery []types.BulkEmailDestination
-
-	for i := 0; i < len(fields); i += fieldsPerRecord {
-		action := fields[i]
-		email := fields[i+1]
-		username := fields[i+2]
-		secret := fields[i+3]
-		code := fields[i+4]
-
-		switch action {
-		case "activation":
-			destinationsActivation = append(destinationsActivation, types.BulkEmailDestination{
-				Destination: &types.Destination{
-					ToAddresses: []string{email},
-				},
-				ReplacementTemplateData: aws.String(fmt.Sprintf("{\"login\":\"%s\",\"secret\":\"%s\"}", username, secret)),
-			})
-		case "password_recovery":
-			destinationsPasswordRecovery = append(destinationsPasswordRecovery, types.BulkEmailDestination{
-				Destination: &types.Destination{
-					ToAddresses: []string{email},
-				},
-				ReplacementTemplateData: aws.String(fmt.Sprintf("{\"login\":\"%s\",\"secret\":\"%s\",\"code\":\"%s\"}", username, secret, code)),
-			})
-		default:
-			fmt.Fprintf(os.Stderr, "[WARN] unknown action %q, skipping destination...\n", action)
-		}
+	if *serve {
+		runServer(d, cfg)
+		return
 	}
 
-	return destinationsActivation, destinationsPasswordRecovery, nil
+	runStdin(d, cfg)
 }
 
-func sendBulkEmails(client awsSES, fromEmail, template, configSet string, destinations []types.BulkEmailDestination, defaultTemplateData string) {
-	input := &ses.SendBulkTemplatedEmailInput{
-		Source:               aws.String(fromEmail),
-		Template:             aws.String(template),
-		ConfigurationSetName: aws.String(configSet),
-		DefaultTemplateData:  aws.String(defaultTemplateData),
-		Destinations:         destinations,
-	}
-
-	ctx := context.Background()
-	output, err := client.SendBulkTemplatedEmail(ctx, input)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "[ERROR] failed to send bulk templated email: %v\n", err)
-		return
-	}
-
-	fmt.Fprintf(os.Stderr, "[INFO] sent batch with %d destinations using template: %s\n", len(destinations), template)
-	for i, status := range output.Status {
-		recipient := input.Destinations[i].Destination.ToAddresses[0]
-		fmt.Fprintf(os.Stderr, "Recipient: %s, Status: %s\n", recipient, status.Status)
+// runServer starts the long-running HTTP/gRPC ingestion server and blocks
+// until it's interrupted.
+func runServer(d *dispatch.Dispatcher, cfg *config.Config) {
+	srv := server.New(d, cfg)
+	if err := srv.Run(context.Background(), *httpAddr, *grpcAddr); err != nil {
+		logrus.WithError(err).Fatal("server exited")
 	}
 }
 
-type mockSESClient struct{}
-
-func (m *mockSESClient) SendBulkTemplatedEmail(ctx context.Context, params *ses.SendBulkTemplatedEmailInput, optFns ...func(*ses.Options)) (*ses.SendBulkTemplatedEmailOutput, error) {
-	fmt.Println("[DEBUG] Mock SendBulkTemplatedEmail called")
-	for _, dest := range params.Destinations {
-		fmt.Printf("[DEBUG] To: %v, TemplateData: %v\n", dest.Destination.ToAddresses, dest.ReplacementTemplateData)
+// runStdin reads one-off batch input from STDIN until EOF, in the format
+// selected by -input.
+func runStdin(d *dispatch.Dispatcher, cfg *config.Config) {
+	d.RetryDue(context.Background(), cfg)
+
+	var err error
+	switch *inputFormat {
+	case "jsonl":
+		err = ingestJSONL(d, cfg, os.Stdin)
+	case "csv":
+		err = ingestCSV(d, cfg, os.Stdin)
+	default:
+		logrus.Fatalf("unknown -input format %q; want csv or jsonl", *inputFormat)
 	}
-	statuses := make([]types.BulkEmailDestinationStatus, len(params.Destinations))
-	for i := range statuses {
-		statuses[i] = types.BulkEmailDestinationStatus{Status: "Success"}
+	if err != nil {
+		logrus.WithError(err).Error("failed to ingest input")
 	}
-	return &ses.SendBulkTemplatedEmailOutput{Status: statuses}, nil
+
+	d.RetryDue(context.Background(), cfg)
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+)
+
+// mockSESClient is used in -debug mode to exercise the pipeline without an
+// AWS account.
+type mockSESClient struct{}
+
+func (m *mockSESClient) SendBulkTemplatedEmail(ctx context.Context, params *ses.SendBulkTemplatedEmailInput, optFns ...func(*ses.Options)) (*ses.SendBulkTemplatedEmailOutput, error) {
+	fmt.Println("[DEBUG] Mock SendBulkTemplatedEmail called")
+	for _, dest := range params.Destinations {
+		fmt.Printf("[DEBUG] To: %v, TemplateData: %v\n", dest.Destination.ToAddresses, dest.ReplacementTemplateData)
+	}
+	statuses := make([]types.BulkEmailDestinationStatus, len(params.Destinations))
+	for i := range statuses {
+		statuses[i] = types.BulkEmailDestinationStatus{Status: types.BulkEmailStatusSuccess}
+	}
+	return &ses.SendBulkTemplatedEmailOutput{Status: statuses}, nil
+}
+
+func (m *mockSESClient) SendEmail(ctx context.Context, params *ses.SendEmailInput, optFns ...func(*ses.Options)) (*ses.SendEmailOutput, error) {
+	fmt.Printf("[DEBUG] Mock SendEmail called: To: %v, Subject: %v\n", params.Destination.ToAddresses, params.Message.Subject.Data)
+	return &ses.SendEmailOutput{MessageId: aws.String("mock-message-id")}, nil
+}
@@ -0,0 +1,97 @@
+// Package config loads the TOML configuration that drives mailroom's
+// campaign templates and delivery provider.
+package config
+
+import (
+	"fmt"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ProviderKind selects which Sender implementation serves a Config.
+type ProviderKind string
+
+const (
+	ProviderSES  ProviderKind = "ses"
+	ProviderSMTP ProviderKind = "smtp"
+)
+
+// SMTPConfig holds the connection details used when Provider is "smtp".
+type SMTPConfig struct {
+	Host     string `toml:"host"`
+	Port     int    `toml:"port"`
+	TLS      bool   `toml:"tls"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+// Template describes a single named campaign: where it's sent from, which
+// provider-side template renders it, and the CSV fields required to build
+// its replacement data.
+type Template struct {
+	Name             string   `toml:"-"`
+	From             string   `toml:"from"`
+	TemplateName     string   `toml:"template_name"`
+	ConfigurationSet string   `toml:"configuration_set"`
+	Fields           []string `toml:"fields"`
+	DefaultDataJSON  string   `toml:"default_data"`
+
+	// LocalDir, if set, points at a directory holding subject.tmpl and
+	// body.html.tmpl/body.txt.tmpl. When present, mailroom renders the
+	// message itself with html/template and text/template instead of
+	// relying on an SES-side template named by TemplateName.
+	LocalDir string `toml:"local_dir"`
+}
+
+// Local reports whether this template renders locally rather than
+// referencing a provider-side template.
+func (t Template) Local() bool {
+	return t.LocalDir != ""
+}
+
+// ValidateData checks that data carries a non-empty value for every field
+// the template declares, so a malformed CSV record is rejected before
+// it's sent rather than rendering a template with blanks.
+func (t Template) ValidateData(data map[string]string) error {
+	for _, field := range t.Fields {
+		if data[field] == "" {
+			return fmt.Errorf("template %q requires field %q", t.Name, field)
+		}
+	}
+	return nil
+}
+
+// Config is the top-level mailroom configuration.
+type Config struct {
+	Provider  ProviderKind        `toml:"provider"`
+	SMTP      SMTPConfig          `toml:"smtp"`
+	Templates map[string]Template `toml:"templates"`
+}
+
+// Load parses TOML configuration bytes into a Config, filling in each
+// Template's Name from its map key.
+func Load(data []byte) (*Config, error) {
+	var cfg Config
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderSES
+	}
+
+	if len(cfg.Templates) == 0 {
+		return nil, fmt.Errorf("config declares no templates")
+	}
+
+	for name, tmpl := range cfg.Templates {
+		tmpl.Name = name
+		cfg.Templates[name] = tmpl
+
+		if cfg.Provider == ProviderSMTP && !tmpl.Local() {
+			return nil, fmt.Errorf("template %q: smtp provider has no equivalent of an SES-side template; set local_dir", name)
+		}
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import "testing"
+
+func TestLoadFillsTemplateNameFromKey(t *testing.T) {
+	cfg, err := Load([]byte(`
+provider = "ses"
+
+[templates.activation]
+from = "noreply@example.com"
+template_name = "Activation"
+fields = ["login"]
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tmpl, ok := cfg.Templates["activation"]
+	if !ok {
+		t.Fatalf("Templates[%q] missing", "activation")
+	}
+	if tmpl.Name != "activation" {
+		t.Fatalf("Name = %q, want %q", tmpl.Name, "activation")
+	}
+}
+
+func TestLoadDefaultsProviderToSES(t *testing.T) {
+	cfg, err := Load([]byte(`
+[templates.activation]
+from = "noreply@example.com"
+template_name = "Activation"
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Provider != ProviderSES {
+		t.Fatalf("Provider = %q, want %q", cfg.Provider, ProviderSES)
+	}
+}
+
+func TestLoadRejectsEmptyTemplates(t *testing.T) {
+	if _, err := Load([]byte(`provider = "ses"`)); err == nil {
+		t.Fatal("Load() with no templates: error = nil, want non-nil")
+	}
+}
+
+func TestLoadRejectsSMTPTemplateWithoutLocalDir(t *testing.T) {
+	_, err := Load([]byte(`
+provider = "smtp"
+
+[templates.activation]
+from = "noreply@example.com"
+template_name = "Activation"
+`))
+	if err == nil {
+		t.Fatal("Load() with smtp provider and no local_dir: error = nil, want non-nil")
+	}
+}
+
+func TestLoadAcceptsSMTPTemplateWithLocalDir(t *testing.T) {
+	_, err := Load([]byte(`
+provider = "smtp"
+
+[templates.activation]
+from = "noreply@example.com"
+local_dir = "templates/activation"
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+}
+
+func TestTemplateLocal(t *testing.T) {
+	if (Template{}).Local() {
+		t.Fatal("Local() with no LocalDir = true, want false")
+	}
+	if !(Template{LocalDir: "templates/activation"}).Local() {
+		t.Fatal("Local() with LocalDir set = false, want true")
+	}
+}
+
+func TestTemplateValidateData(t *testing.T) {
+	tmpl := Template{Name: "activation", Fields: []string{"login", "secret"}}
+
+	if err := tmpl.ValidateData(map[string]string{"login": "a", "secret": "b"}); err != nil {
+		t.Fatalf("ValidateData() with all fields present error = %v", err)
+	}
+
+	if err := tmpl.ValidateData(map[string]string{"login": "a"}); err == nil {
+		t.Fatal("ValidateData() with missing field: error = nil, want non-nil")
+	}
+
+	if err := tmpl.ValidateData(map[string]string{"login": "a", "secret": ""}); err == nil {
+		t.Fatal("ValidateData() with empty field: error = nil, want non-nil")
+	}
+}
@@ -0,0 +1,227 @@
+// Package dispatch wires a sender.Sender, a retry.Queue, and structured
+// logging into the single send pipeline shared by mailroom's CLI and
+// server modes.
+package dispatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/render"
+	"github.com/tetsuo/mailroom/emailutils/retry"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// MaxBatchSize is SES's limit on destinations per SendBulkTemplatedEmail
+// call. Anything that accumulates destinations across more than one Send
+// call — the CLI's CSV/JSONL ingestion, the server's batching flusher —
+// must chunk to this size before calling Send.
+const MaxBatchSize = 50
+
+// Stats are the counters reported in the shutdown summary. They are not
+// exported to Prometheus; server mode keeps its own per-template counters
+// through OnResult. Safe for concurrent use.
+type Stats struct {
+	Sent         int64
+	Failed       int64
+	Retried      int64
+	DeadLettered int64
+}
+
+func (s *Stats) logSummary() {
+	logrus.WithFields(logrus.Fields{
+		"sent":          atomic.LoadInt64(&s.Sent),
+		"failed":        atomic.LoadInt64(&s.Failed),
+		"retried":       atomic.LoadInt64(&s.Retried),
+		"dead_lettered": atomic.LoadInt64(&s.DeadLettered),
+	}).Info("mailroom shutting down")
+}
+
+// Dispatcher sends batches through a Sender, logs one structured record per
+// recipient, and routes retryable failures through a retry.Queue.
+type Dispatcher struct {
+	Sender sender.Sender
+	Queue  *retry.Queue
+	Stats  *Stats
+
+	// OnResult, if set, is called for every per-recipient result after it
+	// has been logged and (if applicable) retry-queued. Server mode uses
+	// this to mirror counters onto Prometheus.
+	OnResult func(template string, result sender.Result)
+
+	renderers sync.Map // template name -> *render.Renderer
+}
+
+// New builds a Dispatcher with a fresh Stats counter set.
+func New(s sender.Sender, queue *retry.Queue) *Dispatcher {
+	return &Dispatcher{Sender: s, Queue: queue, Stats: &Stats{}}
+}
+
+// renderer returns the cached Renderer for a local template, loading it
+// from tmpl.LocalDir on first use.
+func (d *Dispatcher) renderer(tmpl config.Template) (*render.Renderer, error) {
+	if v, ok := d.renderers.Load(tmpl.Name); ok {
+		return v.(*render.Renderer), nil
+	}
+
+	r, err := render.Load(tmpl.LocalDir)
+	if err != nil {
+		return nil, err
+	}
+
+	d.renderers.Store(tmpl.Name, r)
+	return r, nil
+}
+
+// sendBatch delivers destinations for tmpl, rendering locally first when
+// tmpl.Local() is set.
+func (d *Dispatcher) sendBatch(ctx context.Context, tmpl config.Template, destinations []sender.Destination) ([]sender.Result, error) {
+	if !tmpl.Local() {
+		return d.Sender.Send(ctx, tmpl, destinations)
+	}
+
+	r, err := d.renderer(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load local template %q: %w", tmpl.Name, err)
+	}
+
+	// Each destination is validated and rendered independently: one bad
+	// record (invalid JSON, a missing field, a template error) must not
+	// sink every other destination already collected in this batch.
+	results := make([]sender.Result, len(destinations))
+	var messages []sender.RenderedMessage
+	var messageDestinations []int // messages[i] came from destinations[messageDestinations[i]]
+
+	for i, dest := range destinations {
+		var data map[string]string
+		if err := json.Unmarshal([]byte(dest.Data), &data); err != nil {
+			results[i] = sender.Result{Email: dest.Email, Err: fmt.Errorf("invalid replacement data: %w", err)}
+			continue
+		}
+		if err := tmpl.ValidateData(data); err != nil {
+			results[i] = sender.Result{Email: dest.Email, Err: err}
+			continue
+		}
+
+		subject, html, text, err := r.Render(data)
+		if err != nil {
+			results[i] = sender.Result{Email: dest.Email, Err: fmt.Errorf("failed to render template %q: %w", tmpl.Name, err)}
+			continue
+		}
+
+		messages = append(messages, sender.RenderedMessage{Email: dest.Email, Subject: subject, HTML: html, Text: text})
+		messageDestinations = append(messageDestinations, i)
+	}
+
+	if len(messages) == 0 {
+		return results, nil
+	}
+
+	sent, err := d.Sender.SendRendered(ctx, tmpl.From, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range sent {
+		results[messageDestinations[i]] = result
+	}
+
+	return results, nil
+}
+
+// Send delivers one batch for tmpl, logging and retry-queueing as results
+// come back.
+func (d *Dispatcher) Send(ctx context.Context, tmpl config.Template, destinations []sender.Destination) {
+	results, err := d.sendBatch(ctx, tmpl, destinations)
+	if err != nil {
+		logrus.WithError(err).WithField("template", tmpl.Name).Error("failed to send bulk templated email")
+		return
+	}
+
+	dataByEmail := make(map[string]string, len(destinations))
+	for _, dest := range destinations {
+		dataByEmail[dest.Email] = dest.Data
+	}
+
+	now := time.Now()
+	for _, result := range results {
+		entry := logrus.WithFields(logrus.Fields{
+			"template":   tmpl.Name,
+			"email":      result.Email,
+			"message_id": result.MessageID,
+			"status":     result.Status,
+		})
+
+		switch {
+		case result.Err == nil && result.Status == "Success":
+			atomic.AddInt64(&d.Stats.Sent, 1)
+			entry.Info("sent")
+		case result.Retryable:
+			atomic.AddInt64(&d.Stats.Retried, 1)
+			entry.WithError(result.Err).Warn("transient failure, queued for retry")
+			if err := d.Queue.Push(tmpl.Name, result.Email, dataByEmail[result.Email], now); err != nil {
+				logrus.WithError(err).Error("failed to push destination onto retry queue")
+			}
+		default:
+			atomic.AddInt64(&d.Stats.Failed, 1)
+			entry.WithError(result.Err).Error("permanent failure")
+		}
+
+		if d.OnResult != nil {
+			d.OnResult(tmpl.Name, result)
+		}
+	}
+}
+
+// RetryDue resends every due item in the retry queue, rescheduling or
+// dead-lettering it on repeated failure.
+func (d *Dispatcher) RetryDue(ctx context.Context, cfg *config.Config) {
+	now := time.Now()
+	items, err := d.Queue.Due(now)
+	if err != nil {
+		logrus.WithError(err).Error("failed to list due retries")
+		return
+	}
+
+	for _, item := range items {
+		tmpl, ok := cfg.Templates[item.Template]
+		if !ok {
+			logrus.WithField("template", item.Template).Warn("retry references unknown template, dropping")
+			_ = d.Queue.Remove(item)
+			continue
+		}
+
+		results, err := d.sendBatch(ctx, tmpl, []sender.Destination{{Email: item.Email, Data: item.Data}})
+		if err == nil && len(results) == 1 && results[0].Err == nil && results[0].Status == "Success" {
+			atomic.AddInt64(&d.Stats.Sent, 1)
+			logrus.WithFields(logrus.Fields{"template": tmpl.Name, "email": item.Email}).Info("retry succeeded")
+			_ = d.Queue.Remove(item)
+			continue
+		}
+
+		deadLettered, rerr := d.Queue.Reschedule(item, now)
+		if rerr != nil {
+			logrus.WithError(rerr).Error("failed to reschedule retry item")
+			continue
+		}
+		if deadLettered {
+			atomic.AddInt64(&d.Stats.DeadLettered, 1)
+			logrus.WithFields(logrus.Fields{"template": tmpl.Name, "email": item.Email, "attempts": item.Attempts}).Error("dead-lettered after exceeding max attempts")
+		} else {
+			logrus.WithFields(logrus.Fields{"template": tmpl.Name, "email": item.Email, "attempts": item.Attempts}).Warn("retry failed again, rescheduled")
+		}
+	}
+}
+
+// Shutdown logs the final counters. Call once, when the process is
+// exiting.
+func (d *Dispatcher) Shutdown() {
+	d.Stats.logSummary()
+}
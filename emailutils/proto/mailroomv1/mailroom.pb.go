@@ -0,0 +1,86 @@
+// Package mailroomv1 hand-implements the Go types for
+// emailutils/proto/mailroom.proto using the legacy github.com/golang/protobuf
+// API, because this tree has no protoc/protoc-gen-go available to generate
+// them. This file is maintained by hand, NOT by `make proto` — running that
+// target requires protoc and the go/go-grpc plugins to be installed, and its
+// real output (APIv2 style, with file descriptor bytes and a
+// file_mailroom_proto_init) should replace this file wholesale rather than
+// merge with it.
+package mailroomv1
+
+import (
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Recipient is a single send target: an email address plus the
+// replacement data to merge into its template.
+type Recipient struct {
+	Email string            `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Data  map[string]string `protobuf:"bytes,2,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *Recipient) Reset()         { *m = Recipient{} }
+func (m *Recipient) String() string { return proto.CompactTextString(m) }
+func (*Recipient) ProtoMessage()    {}
+
+func (m *Recipient) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *Recipient) GetData() map[string]string {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// SendRequest is the payload for MailroomService.Send: a template name and
+// the recipients to batch under it.
+type SendRequest struct {
+	Template   string       `protobuf:"bytes,1,opt,name=template,proto3" json:"template,omitempty"`
+	Recipients []*Recipient `protobuf:"bytes,2,rep,name=recipients,proto3" json:"recipients,omitempty"`
+}
+
+func (m *SendRequest) Reset()         { *m = SendRequest{} }
+func (m *SendRequest) String() string { return proto.CompactTextString(m) }
+func (*SendRequest) ProtoMessage()    {}
+
+func (m *SendRequest) GetTemplate() string {
+	if m != nil {
+		return m.Template
+	}
+	return ""
+}
+
+func (m *SendRequest) GetRecipients() []*Recipient {
+	if m != nil {
+		return m.Recipients
+	}
+	return nil
+}
+
+// SendResponse reports how many recipients were accepted onto the batch
+// flusher.
+type SendResponse struct {
+	Accepted int32 `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+}
+
+func (m *SendResponse) Reset()         { *m = SendResponse{} }
+func (m *SendResponse) String() string { return proto.CompactTextString(m) }
+func (*SendResponse) ProtoMessage()    {}
+
+func (m *SendResponse) GetAccepted() int32 {
+	if m != nil {
+		return m.Accepted
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Recipient)(nil), "mailroom.v1.Recipient")
+	proto.RegisterType((*SendRequest)(nil), "mailroom.v1.SendRequest")
+	proto.RegisterType((*SendResponse)(nil), "mailroom.v1.SendResponse")
+}
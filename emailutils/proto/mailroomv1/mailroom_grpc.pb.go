@@ -0,0 +1,92 @@
+// Hand-written service glue for emailutils/proto/mailroom.proto, matching
+// the shape protoc-gen-go-grpc would emit (MailroomServiceClient/Server,
+// UnimplementedMailroomServiceServer, RegisterMailroomServiceServer). Kept
+// alongside mailroom.pb.go's hand-written message types since this tree has
+// no protoc-gen-go-grpc available; see that file's header for the same
+// "not real generated output" caveat.
+package mailroomv1
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+const (
+	MailroomService_Send_FullMethodName = "/mailroom.v1.MailroomService/Send"
+)
+
+// MailroomServiceClient is the client API for MailroomService.
+type MailroomServiceClient interface {
+	Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error)
+}
+
+type mailroomServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewMailroomServiceClient builds a client around an existing connection.
+func NewMailroomServiceClient(cc grpc.ClientConnInterface) MailroomServiceClient {
+	return &mailroomServiceClient{cc}
+}
+
+func (c *mailroomServiceClient) Send(ctx context.Context, in *SendRequest, opts ...grpc.CallOption) (*SendResponse, error) {
+	out := new(SendResponse)
+	err := c.cc.Invoke(ctx, MailroomService_Send_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MailroomServiceServer is the server API for MailroomService.
+type MailroomServiceServer interface {
+	Send(context.Context, *SendRequest) (*SendResponse, error)
+}
+
+// UnimplementedMailroomServiceServer must be embedded by implementations
+// that don't implement every method, so adding RPCs doesn't break them at
+// compile time.
+type UnimplementedMailroomServiceServer struct{}
+
+func (UnimplementedMailroomServiceServer) Send(context.Context, *SendRequest) (*SendResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Send not implemented")
+}
+
+// RegisterMailroomServiceServer registers srv with s.
+func RegisterMailroomServiceServer(s grpc.ServiceRegistrar, srv MailroomServiceServer) {
+	s.RegisterService(&_MailroomService_serviceDesc, srv)
+}
+
+func _MailroomService_Send_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MailroomServiceServer).Send(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MailroomService_Send_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MailroomServiceServer).Send(ctx, req.(*SendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MailroomService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "mailroom.v1.MailroomService",
+	HandlerType: (*MailroomServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Send",
+			Handler:    _MailroomService_Send_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "emailutils/proto/mailroom.proto",
+}
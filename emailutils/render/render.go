@@ -0,0 +1,95 @@
+// Package render loads a template's subject/HTML/text files from disk and
+// renders them locally, for templates that don't have an SES-side template
+// to fall back on.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+)
+
+const (
+	subjectFile = "subject.tmpl"
+	htmlFile    = "body.html.tmpl"
+	textFile    = "body.txt.tmpl"
+)
+
+// Renderer holds a template's parsed subject, HTML body, and text body,
+// ready to be merged with per-recipient data.
+type Renderer struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// Load parses subject.tmpl, body.html.tmpl, and body.txt.tmpl out of dir.
+// body.html.tmpl and body.txt.tmpl are each optional, but at least one of
+// them must be present.
+func Load(dir string) (*Renderer, error) {
+	subject, err := texttemplate.ParseFiles(filepath.Join(dir, subjectFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", subjectFile, err)
+	}
+
+	r := &Renderer{subject: subject}
+
+	if path := filepath.Join(dir, htmlFile); fileExists(path) {
+		r.html, err = htmltemplate.ParseFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", htmlFile, err)
+		}
+	}
+
+	if path := filepath.Join(dir, textFile); fileExists(path) {
+		r.text, err = texttemplate.ParseFiles(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", textFile, err)
+		}
+	}
+
+	if r.html == nil && r.text == nil {
+		return nil, fmt.Errorf("template directory %s has neither %s nor %s", dir, htmlFile, textFile)
+	}
+
+	return r, nil
+}
+
+// Render merges data into the subject, HTML body, and text body. The HTML
+// or text body is left empty if that variant wasn't present in the
+// template directory.
+func (r *Renderer) Render(data map[string]string) (subject, html, text string, err error) {
+	var buf bytes.Buffer
+
+	buf.Reset()
+	if err := r.subject.Execute(&buf, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render subject: %w", err)
+	}
+	subject = buf.String()
+
+	if r.html != nil {
+		buf.Reset()
+		if err := r.html.Execute(&buf, data); err != nil {
+			return "", "", "", fmt.Errorf("failed to render HTML body: %w", err)
+		}
+		html = buf.String()
+	}
+
+	if r.text != nil {
+		buf.Reset()
+		if err := r.text.Execute(&buf, data); err != nil {
+			return "", "", "", fmt.Errorf("failed to render text body: %w", err)
+		}
+		text = buf.String()
+	}
+
+	return subject, html, text, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTemplateDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("WriteFile(%s) error = %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadAndRenderHTMLAndText(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		subjectFile: "Welcome, {{.login}}!",
+		htmlFile:    "<p>Hi {{.login}}, click <a href=\"{{.link}}\">here</a>.</p>",
+		textFile:    "Hi {{.login}}, visit {{.link}}",
+	})
+
+	r, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	subject, html, text, err := r.Render(map[string]string{"login": "ana", "link": "https://example.com"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if subject != "Welcome, ana!" {
+		t.Fatalf("subject = %q", subject)
+	}
+	if html != `<p>Hi ana, click <a href="https://example.com">here</a>.</p>` {
+		t.Fatalf("html = %q", html)
+	}
+	if text != "Hi ana, visit https://example.com" {
+		t.Fatalf("text = %q", text)
+	}
+}
+
+func TestLoadRequiresAtLeastOneBody(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		subjectFile: "Welcome, {{.login}}!",
+	})
+
+	if _, err := Load(dir); err == nil {
+		t.Fatal("Load() with neither HTML nor text body: error = nil, want non-nil")
+	}
+}
+
+func TestLoadRequiresSubject(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		textFile: "hi",
+	})
+
+	if _, err := Load(dir); err == nil {
+		t.Fatal("Load() with no subject.tmpl: error = nil, want non-nil")
+	}
+}
+
+func TestRenderHTMLEscapesData(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		subjectFile: "hi",
+		htmlFile:    "<p>{{.name}}</p>",
+	})
+
+	r, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	_, html, _, err := r.Render(map[string]string{"name": "<script>alert(1)</script>"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if html == "<p><script>alert(1)</script></p>" {
+		t.Fatal("Render() did not escape HTML-unsafe data in the HTML body")
+	}
+}
+
+func TestLoadMissingTemplateDirError(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "does-not-exist")); err == nil {
+		t.Fatal("Load() with missing directory: error = nil, want non-nil")
+	}
+}
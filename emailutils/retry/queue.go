@@ -0,0 +1,88 @@
+// Package retry implements a bounded, disk-backed retry queue for
+// destinations that failed to send, so a crash mid-batch doesn't lose
+// recipients.
+package retry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asdine/storm/v3"
+)
+
+// Item is one queued retry: enough to rebuild a sender.Destination and
+// resend it against the same template.
+type Item struct {
+	ID          int `storm:"id,increment"`
+	Template    string
+	Email       string
+	Data        string
+	Attempts    int
+	NextAttempt time.Time `storm:"index"`
+}
+
+// Queue is a FIFO-ish retry queue with exponential backoff and a maximum
+// attempt count, persisted to a BoltDB file via storm.
+type Queue struct {
+	db          *storm.DB
+	maxAttempts int
+	baseBackoff time.Duration
+}
+
+// Open opens (or creates) the retry queue database at path.
+func Open(path string, maxAttempts int, baseBackoff time.Duration) (*Queue, error) {
+	db, err := storm.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retry queue %s: %w", path, err)
+	}
+	return &Queue{db: db, maxAttempts: maxAttempts, baseBackoff: baseBackoff}, nil
+}
+
+// Close releases the underlying BoltDB handle.
+func (q *Queue) Close() error {
+	return q.db.Close()
+}
+
+// Push enqueues a destination for its first retry attempt.
+func (q *Queue) Push(template, email, data string, now time.Time) error {
+	return q.db.Save(&Item{
+		Template:    template,
+		Email:       email,
+		Data:        data,
+		Attempts:    0,
+		NextAttempt: now.Add(q.baseBackoff),
+	})
+}
+
+// Due returns all items whose NextAttempt has passed.
+func (q *Queue) Due(now time.Time) ([]Item, error) {
+	var items []Item
+	if err := q.db.All(&items); err != nil {
+		return nil, fmt.Errorf("failed to list retry queue: %w", err)
+	}
+
+	var due []Item
+	for _, item := range items {
+		if !item.NextAttempt.After(now) {
+			due = append(due, item)
+		}
+	}
+	return due, nil
+}
+
+// Remove deletes an item after it has been successfully resent.
+func (q *Queue) Remove(item Item) error {
+	return q.db.DeleteStruct(&item)
+}
+
+// Reschedule bumps an item's attempt count and backoff, or reports it as
+// dead-lettered (and removes it) once the attempt count reaches maxAttempts.
+func (q *Queue) Reschedule(item Item, now time.Time) (deadLettered bool, err error) {
+	item.Attempts++
+	if item.Attempts >= q.maxAttempts {
+		return true, q.db.DeleteStruct(&item)
+	}
+
+	item.NextAttempt = now.Add(q.baseBackoff * (1 << item.Attempts))
+	return false, q.db.Update(&item)
+}
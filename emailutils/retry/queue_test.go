@@ -0,0 +1,155 @@
+package retry
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestQueue(t *testing.T, maxAttempts int, baseBackoff time.Duration) *Queue {
+	t.Helper()
+
+	q, err := Open(filepath.Join(t.TempDir(), "retry.db"), maxAttempts, baseBackoff)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+
+	return q
+}
+
+func TestQueuePushAndDue(t *testing.T) {
+	q := openTestQueue(t, 5, time.Minute)
+	now := time.Now()
+
+	if err := q.Push("activation", "a@example.com", `{"login":"a"}`, now); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if due, err := q.Due(now); err != nil {
+		t.Fatalf("Due() error = %v", err)
+	} else if len(due) != 0 {
+		t.Fatalf("Due() before backoff elapsed = %d items, want 0", len(due))
+	}
+
+	later := now.Add(time.Minute)
+	due, err := q.Due(later)
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("Due() after backoff elapsed = %d items, want 1", len(due))
+	}
+	if due[0].Email != "a@example.com" || due[0].Template != "activation" {
+		t.Fatalf("Due() item = %+v, want activation/a@example.com", due[0])
+	}
+}
+
+func TestQueueRemove(t *testing.T) {
+	q := openTestQueue(t, 5, time.Minute)
+	now := time.Now()
+
+	if err := q.Push("activation", "a@example.com", "{}", now); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	due, err := q.Due(now.Add(time.Minute))
+	if err != nil || len(due) != 1 {
+		t.Fatalf("Due() = %v, %v, want 1 item", due, err)
+	}
+
+	if err := q.Remove(due[0]); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	due, err = q.Due(now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("Due() after Remove() = %d items, want 0", len(due))
+	}
+}
+
+func TestQueueRescheduleBacksOff(t *testing.T) {
+	q := openTestQueue(t, 5, time.Minute)
+	now := time.Now()
+
+	if err := q.Push("activation", "a@example.com", "{}", now); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	due, err := q.Due(now.Add(time.Minute))
+	if err != nil || len(due) != 1 {
+		t.Fatalf("Due() = %v, %v, want 1 item", due, err)
+	}
+
+	deadLettered, err := q.Reschedule(due[0], now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Reschedule() error = %v", err)
+	}
+	if deadLettered {
+		t.Fatalf("Reschedule() dead-lettered = true on attempt 1, want false")
+	}
+
+	// Backoff doubles: item should not be due again after only one more
+	// base interval, since Reschedule set NextAttempt to now + 2*backoff.
+	notYet, err := q.Due(now.Add(2 * time.Minute))
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(notYet) != 0 {
+		t.Fatalf("Due() right after doubled backoff = %d items, want 0", len(notYet))
+	}
+
+	afterBackoff, err := q.Due(now.Add(3 * time.Minute))
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(afterBackoff) != 1 {
+		t.Fatalf("Due() after doubled backoff elapses = %d items, want 1", len(afterBackoff))
+	}
+	if afterBackoff[0].Attempts != 1 {
+		t.Fatalf("Attempts = %d, want 1", afterBackoff[0].Attempts)
+	}
+}
+
+func TestQueueRescheduleDeadLettersAtMaxAttempts(t *testing.T) {
+	q := openTestQueue(t, 2, time.Minute)
+	now := time.Now()
+
+	if err := q.Push("activation", "a@example.com", "{}", now); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	due, err := q.Due(now.Add(time.Minute))
+	if err != nil || len(due) != 1 {
+		t.Fatalf("Due() = %v, %v, want 1 item", due, err)
+	}
+
+	deadLettered, err := q.Reschedule(due[0], now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Reschedule() error = %v", err)
+	}
+	if deadLettered {
+		t.Fatalf("Reschedule() dead-lettered on attempt 1 with maxAttempts=2, want false")
+	}
+
+	item := due[0]
+	item.Attempts = 1
+	deadLettered, err = q.Reschedule(item, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Reschedule() error = %v", err)
+	}
+	if !deadLettered {
+		t.Fatalf("Reschedule() dead-lettered on attempt 2 with maxAttempts=2, want true")
+	}
+
+	remaining, err := q.Due(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Due() error = %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Fatalf("Due() after dead-letter = %d items, want 0 (item should be removed)", len(remaining))
+	}
+}
@@ -0,0 +1,60 @@
+// Package sender provides the Sender abstraction mailroom uses to deliver
+// bulk template emails through whichever provider a Config selects.
+package sender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+)
+
+// Destination is a single recipient and the replacement data to merge into
+// its template.
+type Destination struct {
+	Email string
+	Data  string // JSON-encoded replacement data
+}
+
+// Result reports the delivery outcome for one Destination in a batch.
+type Result struct {
+	Email     string
+	Status    string
+	MessageID string
+	Err       error
+	// Retryable marks a failure worth retrying (e.g. provider throttling),
+	// as opposed to a permanent rejection.
+	Retryable bool
+}
+
+// RenderedMessage is a single recipient plus a subject/HTML/text body
+// already rendered from a local template, for templates without a
+// provider-side counterpart.
+type RenderedMessage struct {
+	Email   string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender delivers a batch of Destinations for a single Template, returning
+// one Result per Destination in the same order.
+type Sender interface {
+	Send(ctx context.Context, tmpl config.Template, destinations []Destination) ([]Result, error)
+
+	// SendRendered delivers locally-rendered messages, one Result per
+	// message in the same order, bypassing any provider-side template.
+	SendRendered(ctx context.Context, from string, messages []RenderedMessage) ([]Result, error)
+}
+
+// New builds the Sender selected by cfg.Provider.
+func New(ctx context.Context, cfg *config.Config) (Sender, error) {
+	switch cfg.Provider {
+	case config.ProviderSES, "":
+		return NewSES(ctx)
+	case config.ProviderSMTP:
+		return NewSMTP(cfg.SMTP), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+}
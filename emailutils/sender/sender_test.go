@@ -0,0 +1,24 @@
+package sender
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+)
+
+func TestNewSelectsProvider(t *testing.T) {
+	s, err := New(context.Background(), &config.Config{Provider: config.ProviderSMTP})
+	if err != nil {
+		t.Fatalf("New() with smtp provider error = %v", err)
+	}
+	if _, ok := s.(*SMTP); !ok {
+		t.Fatalf("New() with smtp provider = %T, want *SMTP", s)
+	}
+}
+
+func TestNewRejectsUnknownProvider(t *testing.T) {
+	if _, err := New(context.Background(), &config.Config{Provider: "mailgun"}); err == nil {
+		t.Fatal("New() with unknown provider: error = nil, want non-nil")
+	}
+}
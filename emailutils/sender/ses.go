@@ -0,0 +1,138 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+)
+
+// sesAPI is the subset of the SES client SES needs, so tests can supply a
+// mock implementation.
+type sesAPI interface {
+	SendBulkTemplatedEmail(ctx context.Context, params *ses.SendBulkTemplatedEmailInput, optFns ...func(*ses.Options)) (*ses.SendBulkTemplatedEmailOutput, error)
+	SendEmail(ctx context.Context, params *ses.SendEmailInput, optFns ...func(*ses.Options)) (*ses.SendEmailOutput, error)
+}
+
+// SES sends bulk templated email through Amazon SES.
+type SES struct {
+	client sesAPI
+}
+
+// NewSES builds a SES sender using the default AWS config (environment,
+// shared config file, or instance role).
+func NewSES(ctx context.Context) (*SES, error) {
+	awscfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS default config: %w", err)
+	}
+	return &SES{client: ses.NewFromConfig(awscfg)}, nil
+}
+
+// NewSESWithClient builds a SES sender around an existing client, mainly
+// for tests.
+func NewSESWithClient(client sesAPI) *SES {
+	return &SES{client: client}
+}
+
+func (s *SES) Send(ctx context.Context, tmpl config.Template, destinations []Destination) ([]Result, error) {
+	dests := make([]types.BulkEmailDestination, len(destinations))
+	for i, d := range destinations {
+		dests[i] = types.BulkEmailDestination{
+			Destination:             &types.Destination{ToAddresses: []string{d.Email}},
+			ReplacementTemplateData: aws.String(d.Data),
+		}
+	}
+
+	input := &ses.SendBulkTemplatedEmailInput{
+		Source:               aws.String(tmpl.From),
+		Template:             aws.String(tmpl.TemplateName),
+		ConfigurationSetName: aws.String(tmpl.ConfigurationSet),
+		DefaultTemplateData:  aws.String(tmpl.DefaultDataJSON),
+		Destinations:         dests,
+	}
+
+	output, err := s.client.SendBulkTemplatedEmail(ctx, input)
+	if err != nil {
+		if !isTransientAPIError(err) {
+			return nil, fmt.Errorf("failed to send bulk templated email: %w", err)
+		}
+
+		results := make([]Result, len(destinations))
+		for i, d := range destinations {
+			results[i] = Result{Email: d.Email, Err: err, Retryable: true}
+		}
+		return results, nil
+	}
+
+	results := make([]Result, len(destinations))
+	for i, status := range output.Status {
+		results[i] = Result{
+			Email:     destinations[i].Email,
+			Status:    string(status.Status),
+			MessageID: aws.ToString(status.MessageId),
+			Retryable: isTransientStatus(status.Status),
+		}
+	}
+
+	return results, nil
+}
+
+// SendRendered delivers each locally-rendered message with its own
+// SendEmail call, since SES templates aren't involved.
+func (s *SES) SendRendered(ctx context.Context, from string, messages []RenderedMessage) ([]Result, error) {
+	results := make([]Result, len(messages))
+
+	for i, m := range messages {
+		body := &types.Body{}
+		if m.HTML != "" {
+			body.Html = &types.Content{Data: aws.String(m.HTML)}
+		}
+		if m.Text != "" {
+			body.Text = &types.Content{Data: aws.String(m.Text)}
+		}
+
+		input := &ses.SendEmailInput{
+			Source:      aws.String(from),
+			Destination: &types.Destination{ToAddresses: []string{m.Email}},
+			Message: &types.Message{
+				Subject: &types.Content{Data: aws.String(m.Subject)},
+				Body:    body,
+			},
+		}
+
+		output, err := s.client.SendEmail(ctx, input)
+		if err != nil {
+			results[i] = Result{Email: m.Email, Err: err, Retryable: isTransientAPIError(err)}
+			continue
+		}
+
+		results[i] = Result{Email: m.Email, Status: "Success", MessageID: aws.ToString(output.MessageId)}
+	}
+
+	return results, nil
+}
+
+// isTransientAPIError reports whether err is a retryable SES API-level
+// failure, such as request throttling or a transient service outage.
+func isTransientAPIError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Throttling") || strings.Contains(msg, "ServiceUnavailable")
+}
+
+// isTransientStatus reports whether a per-destination BulkEmailStatus is
+// worth retrying rather than a permanent rejection.
+func isTransientStatus(status types.BulkEmailStatus) bool {
+	switch status {
+	case types.BulkEmailStatusTransientFailure, types.BulkEmailStatusAccountThrottled:
+		return true
+	default:
+		return false
+	}
+}
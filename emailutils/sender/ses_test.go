@@ -0,0 +1,116 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+)
+
+type mockSESAPI struct {
+	bulkOutput *ses.SendBulkTemplatedEmailOutput
+	bulkErr    error
+	bulkInput  *ses.SendBulkTemplatedEmailInput
+
+	emailOutput *ses.SendEmailOutput
+	emailErr    error
+}
+
+func (m *mockSESAPI) SendBulkTemplatedEmail(ctx context.Context, params *ses.SendBulkTemplatedEmailInput, optFns ...func(*ses.Options)) (*ses.SendBulkTemplatedEmailOutput, error) {
+	m.bulkInput = params
+	return m.bulkOutput, m.bulkErr
+}
+
+func (m *mockSESAPI) SendEmail(ctx context.Context, params *ses.SendEmailInput, optFns ...func(*ses.Options)) (*ses.SendEmailOutput, error) {
+	return m.emailOutput, m.emailErr
+}
+
+func TestSESSendMapsPerDestinationStatus(t *testing.T) {
+	mock := &mockSESAPI{
+		bulkOutput: &ses.SendBulkTemplatedEmailOutput{
+			Status: []types.BulkEmailDestinationStatus{
+				{Status: types.BulkEmailStatusSuccess, MessageId: aws.String("msg-1")},
+				{Status: types.BulkEmailStatusAccountThrottled},
+			},
+		},
+	}
+	s := NewSESWithClient(mock)
+
+	results, err := s.Send(context.Background(), config.Template{From: "noreply@example.com"}, []Destination{
+		{Email: "a@example.com", Data: "{}"},
+		{Email: "b@example.com", Data: "{}"},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].MessageID != "msg-1" || results[0].Retryable {
+		t.Fatalf("results[0] = %+v, want Success/msg-1/not retryable", results[0])
+	}
+	if !results[1].Retryable {
+		t.Fatalf("results[1].Retryable = false for an account-throttled destination, want true")
+	}
+}
+
+func TestSESSendTransientAPIErrorMarksAllRetryable(t *testing.T) {
+	mock := &mockSESAPI{bulkErr: errors.New("Throttling: rate exceeded")}
+	s := NewSESWithClient(mock)
+
+	results, err := s.Send(context.Background(), config.Template{From: "noreply@example.com"}, []Destination{
+		{Email: "a@example.com", Data: "{}"},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v, want nil (transient errors surface as retryable results)", err)
+	}
+	if len(results) != 1 || !results[0].Retryable {
+		t.Fatalf("results = %+v, want one retryable result", results)
+	}
+}
+
+func TestSESSendPermanentAPIErrorFailsBatch(t *testing.T) {
+	mock := &mockSESAPI{bulkErr: errors.New("ValidationError: bad template")}
+	s := NewSESWithClient(mock)
+
+	if _, err := s.Send(context.Background(), config.Template{From: "noreply@example.com"}, []Destination{
+		{Email: "a@example.com", Data: "{}"},
+	}); err == nil {
+		t.Fatal("Send() with a non-transient API error: error = nil, want non-nil")
+	}
+}
+
+func TestSESSendRendered(t *testing.T) {
+	mock := &mockSESAPI{emailOutput: &ses.SendEmailOutput{MessageId: aws.String("msg-1")}}
+	s := NewSESWithClient(mock)
+
+	results, err := s.SendRendered(context.Background(), "noreply@example.com", []RenderedMessage{
+		{Email: "a@example.com", Subject: "hi", HTML: "<p>hi</p>"},
+	})
+	if err != nil {
+		t.Fatalf("SendRendered() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Status != "Success" || results[0].MessageID != "msg-1" {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+func TestSESSendRenderedPerMessageError(t *testing.T) {
+	mock := &mockSESAPI{emailErr: errors.New("Throttling: rate exceeded")}
+	s := NewSESWithClient(mock)
+
+	results, err := s.SendRendered(context.Background(), "noreply@example.com", []RenderedMessage{
+		{Email: "a@example.com", Subject: "hi", Text: "hi"},
+	})
+	if err != nil {
+		t.Fatalf("SendRendered() error = %v", err)
+	}
+	if len(results) != 1 || !results[0].Retryable {
+		t.Fatalf("results = %+v, want one retryable result", results)
+	}
+}
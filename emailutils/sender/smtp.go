@@ -0,0 +1,116 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+
+	gomail "github.com/wneessen/go-mail"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+)
+
+// SMTP sends email directly through an SMTP server, for deployments that
+// don't have an SES template configured.
+type SMTP struct {
+	cfg config.SMTPConfig
+}
+
+// NewSMTP builds an SMTP sender from the given connection settings.
+func NewSMTP(cfg config.SMTPConfig) *SMTP {
+	return &SMTP{cfg: cfg}
+}
+
+func (s *SMTP) client() (*gomail.Client, error) {
+	opts := []gomail.Option{gomail.WithPort(s.cfg.Port)}
+	if s.cfg.TLS {
+		opts = append(opts, gomail.WithTLSPolicy(gomail.TLSMandatory))
+	} else {
+		opts = append(opts, gomail.WithTLSPolicy(gomail.NoTLS))
+	}
+	if s.cfg.Username != "" {
+		opts = append(opts, gomail.WithSMTPAuth(gomail.SMTPAuthPlain),
+			gomail.WithUsername(s.cfg.Username),
+			gomail.WithPassword(s.cfg.Password))
+	}
+
+	return gomail.NewClient(s.cfg.Host, opts...)
+}
+
+// Send exists only to satisfy the Sender interface: plain SMTP has no
+// equivalent of an SES-side named template, so config.Load rejects any
+// smtp-provider template without a LocalDir, and dispatch.Dispatcher
+// always routes those through SendRendered instead. This is never called
+// in practice.
+func (s *SMTP) Send(ctx context.Context, tmpl config.Template, destinations []Destination) ([]Result, error) {
+	return nil, fmt.Errorf("template %q: SMTP provider requires local_dir; SES-side templates aren't supported over SMTP", tmpl.Name)
+}
+
+// SendRendered delivers locally-rendered subject/HTML/text messages.
+func (s *SMTP) SendRendered(ctx context.Context, from string, messages []RenderedMessage) ([]Result, error) {
+	return s.sendMessages(ctx, from, messages)
+}
+
+func (s *SMTP) sendMessages(ctx context.Context, from string, messages []RenderedMessage) ([]Result, error) {
+	client, err := s.client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build SMTP client: %w", err)
+	}
+
+	results := make([]Result, len(messages))
+	var gomsgs []*gomail.Msg
+	var gomsgResults []int // gomsgs[i] corresponds to results[gomsgResults[i]]
+
+	for i, m := range messages {
+		msg := gomail.NewMsg()
+		if err := msg.From(from); err != nil {
+			results[i] = Result{Email: m.Email, Err: err}
+			continue
+		}
+		if err := msg.To(m.Email); err != nil {
+			results[i] = Result{Email: m.Email, Err: err}
+			continue
+		}
+		msg.Subject(m.Subject)
+
+		switch {
+		case m.HTML != "" && m.Text != "":
+			msg.SetBodyString(gomail.TypeTextHTML, m.HTML)
+			msg.AddAlternativeString(gomail.TypeTextPlain, m.Text)
+		case m.HTML != "":
+			msg.SetBodyString(gomail.TypeTextHTML, m.HTML)
+		default:
+			msg.SetBodyString(gomail.TypeTextPlain, m.Text)
+		}
+
+		gomsgs = append(gomsgs, msg)
+		gomsgResults = append(gomsgResults, i)
+		results[i] = Result{Email: m.Email, Status: "Queued"}
+	}
+
+	if err := client.DialAndSendWithContext(ctx, gomsgs...); err != nil {
+		// go-mail sends queued messages sequentially over one connection and
+		// can partially succeed before hitting a failure, so check each
+		// message's own delivery state instead of assuming none of them went
+		// out — otherwise a retry would duplicate-deliver to recipients who
+		// already got it.
+		for i, msg := range gomsgs {
+			idx := gomsgResults[i]
+			if msg.IsDelivered() {
+				results[idx].Status = "Success"
+				continue
+			}
+			sendErr := msg.SendError()
+			if sendErr == nil {
+				sendErr = err
+			}
+			results[idx] = Result{Email: results[idx].Email, Err: sendErr, Retryable: true}
+		}
+		return results, nil
+	}
+
+	for _, idx := range gomsgResults {
+		results[idx].Status = "Success"
+	}
+
+	return results, nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// maxBatchSize mirrors dispatch.MaxBatchSize, SES's limit on destinations
+// per SendBulkTemplatedEmail call.
+const maxBatchSize = dispatch.MaxBatchSize
+
+// flushInterval bounds how long a destination can sit in the batch before
+// being sent, even if the batch hasn't filled up.
+const flushInterval = 2 * time.Second
+
+// flusher batches incoming destinations by template and flushes each batch
+// to the dispatcher once it reaches maxBatchSize or interval elapses,
+// whichever comes first.
+type flusher struct {
+	cfg        *config.Config
+	dispatcher *dispatch.Dispatcher
+	interval   time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]sender.Destination
+}
+
+func newFlusher(cfg *config.Config, d *dispatch.Dispatcher, interval time.Duration) *flusher {
+	return &flusher{
+		cfg:        cfg,
+		dispatcher: d,
+		interval:   interval,
+		pending:    make(map[string][]sender.Destination),
+	}
+}
+
+// enqueue validates the template and appends destinations to its pending
+// batch, flushing immediately any time the batch reaches maxBatchSize.
+func (f *flusher) enqueue(template string, destinations []sender.Destination) error {
+	if _, ok := f.cfg.Templates[template]; !ok {
+		return fmt.Errorf("unknown template %q", template)
+	}
+
+	f.mu.Lock()
+	f.pending[template] = append(f.pending[template], destinations...)
+	full := f.drainLocked(template, maxBatchSize)
+	f.mu.Unlock()
+
+	for _, batch := range full {
+		f.dispatcher.Send(context.Background(), f.cfg.Templates[template], batch)
+	}
+
+	return nil
+}
+
+// drainLocked pulls complete batches of size out of pending[template],
+// leaving any remainder in place. Caller must hold f.mu.
+func (f *flusher) drainLocked(template string, size int) [][]sender.Destination {
+	var batches [][]sender.Destination
+	q := f.pending[template]
+	for len(q) >= size {
+		batches = append(batches, q[:size])
+		q = q[size:]
+	}
+	f.pending[template] = q
+	return batches
+}
+
+// run periodically flushes every template's pending destinations, however
+// small, until ctx is canceled.
+func (f *flusher) run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.flushAll()
+			return
+		case <-ticker.C:
+			f.flushAll()
+		}
+	}
+}
+
+func (f *flusher) flushAll() {
+	f.mu.Lock()
+	batches := make(map[string][]sender.Destination, len(f.pending))
+	for template, destinations := range f.pending {
+		if len(destinations) == 0 {
+			continue
+		}
+		batches[template] = destinations
+		f.pending[template] = nil
+	}
+	f.mu.Unlock()
+
+	for template, destinations := range batches {
+		tmpl, ok := f.cfg.Templates[template]
+		if !ok {
+			logrus.WithField("template", template).Warn("dropping flush for unknown template")
+			continue
+		}
+		for len(destinations) > 0 {
+			n := maxBatchSize
+			if n > len(destinations) {
+				n = len(destinations)
+			}
+			f.dispatcher.Send(context.Background(), tmpl, destinations[:n])
+			destinations = destinations[n:]
+		}
+	}
+}
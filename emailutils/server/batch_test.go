@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	"github.com/tetsuo/mailroom/emailutils/retry"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// fakeSender records every batch it's asked to Send, for assertions on how
+// the flusher grouped destinations.
+type fakeSender struct {
+	mu      sync.Mutex
+	batches [][]sender.Destination
+}
+
+func (f *fakeSender) Send(ctx context.Context, tmpl config.Template, destinations []sender.Destination) ([]sender.Result, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, append([]sender.Destination(nil), destinations...))
+	f.mu.Unlock()
+
+	results := make([]sender.Result, len(destinations))
+	for i, d := range destinations {
+		results[i] = sender.Result{Email: d.Email, Status: "Success"}
+	}
+	return results, nil
+}
+
+func (f *fakeSender) SendRendered(ctx context.Context, from string, messages []sender.RenderedMessage) ([]sender.Result, error) {
+	return nil, nil
+}
+
+func (f *fakeSender) batchCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.batches)
+}
+
+func (f *fakeSender) batchSizes() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sizes := make([]int, len(f.batches))
+	for i, b := range f.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func newTestDispatcher(t *testing.T) (*dispatch.Dispatcher, *fakeSender) {
+	t.Helper()
+
+	q, err := retry.Open(filepath.Join(t.TempDir(), "retry.db"), 5, time.Minute)
+	if err != nil {
+		t.Fatalf("retry.Open() error = %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+
+	fs := &fakeSender{}
+	return dispatch.New(fs, q), fs
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Provider: config.ProviderSES,
+		Templates: map[string]config.Template{
+			"activation": {Name: "activation", From: "noreply@example.com", Fields: []string{"login"}},
+		},
+	}
+}
+
+func destinations(n int) []sender.Destination {
+	dests := make([]sender.Destination, n)
+	for i := range dests {
+		dests[i] = sender.Destination{Email: "user@example.com", Data: `{"login":"user"}`}
+	}
+	return dests
+}
+
+func TestFlusherFlushesOnSize(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	f := newFlusher(testConfig(), d, time.Hour) // long interval: only size should trigger a flush
+
+	if err := f.enqueue("activation", destinations(maxBatchSize)); err != nil {
+		t.Fatalf("enqueue() error = %v", err)
+	}
+
+	if got := fs.batchCount(); got != 1 {
+		t.Fatalf("batchCount() = %d, want 1", got)
+	}
+	if sizes := fs.batchSizes(); len(sizes) != 1 || sizes[0] != maxBatchSize {
+		t.Fatalf("batchSizes() = %v, want [%d]", sizes, maxBatchSize)
+	}
+}
+
+func TestFlusherSplitsOversizedEnqueue(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	f := newFlusher(testConfig(), d, time.Hour)
+
+	if err := f.enqueue("activation", destinations(maxBatchSize+5)); err != nil {
+		t.Fatalf("enqueue() error = %v", err)
+	}
+
+	if got := fs.batchCount(); got != 1 {
+		t.Fatalf("batchCount() = %d, want 1 (one full batch flushed immediately)", got)
+	}
+	if sizes := fs.batchSizes(); sizes[0] != maxBatchSize {
+		t.Fatalf("batchSizes() = %v, want first batch of %d", sizes, maxBatchSize)
+	}
+
+	f.mu.Lock()
+	pending := len(f.pending["activation"])
+	f.mu.Unlock()
+	if pending != 5 {
+		t.Fatalf("pending after enqueue = %d, want 5 (the remainder)", pending)
+	}
+}
+
+func TestFlusherFlushesOnInterval(t *testing.T) {
+	d, fs := newTestDispatcher(t)
+	f := newFlusher(testConfig(), d, 20*time.Millisecond)
+
+	if err := f.enqueue("activation", destinations(3)); err != nil {
+		t.Fatalf("enqueue() error = %v", err)
+	}
+	if got := fs.batchCount(); got != 0 {
+		t.Fatalf("batchCount() before interval elapses = %d, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.run(ctx)
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for fs.batchCount() == 0 {
+		select {
+		case <-deadline:
+			cancel()
+			<-done
+			t.Fatal("flusher never flushed the pending batch on its interval")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+
+	if sizes := fs.batchSizes(); len(sizes) != 1 || sizes[0] != 3 {
+		t.Fatalf("batchSizes() = %v, want [3]", sizes)
+	}
+}
+
+func TestFlusherEnqueueRejectsUnknownTemplate(t *testing.T) {
+	d, _ := newTestDispatcher(t)
+	f := newFlusher(testConfig(), d, time.Hour)
+
+	if err := f.enqueue("does-not-exist", destinations(1)); err == nil {
+		t.Fatal("enqueue() with unknown template: error = nil, want non-nil")
+	}
+}
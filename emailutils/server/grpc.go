@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mailroomv1 "github.com/tetsuo/mailroom/emailutils/proto/mailroomv1"
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// grpcService implements mailroomv1.MailroomServiceServer on top of the
+// same flusher the HTTP API uses.
+type grpcService struct {
+	mailroomv1.UnimplementedMailroomServiceServer
+	flusher *flusher
+}
+
+func (g *grpcService) Send(ctx context.Context, req *mailroomv1.SendRequest) (*mailroomv1.SendResponse, error) {
+	destinations := make([]sender.Destination, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		data, err := json.Marshal(recipient.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient data for %s: %w", recipient.Email, err)
+		}
+		destinations[i] = sender.Destination{Email: recipient.Email, Data: string(data)}
+	}
+
+	if err := g.flusher.enqueue(req.Template, destinations); err != nil {
+		return nil, err
+	}
+
+	return &mailroomv1.SendResponse{Accepted: int32(len(destinations))}, nil
+}
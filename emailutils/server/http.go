@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+// sendRequest is the JSON body accepted by POST /v1/send.
+type sendRequest struct {
+	Template   string `json:"template"`
+	Recipients []struct {
+		Email string            `json:"email"`
+		Data  map[string]string `json:"data"`
+	} `json:"recipients"`
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	destinations := make([]sender.Destination, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		data, err := json.Marshal(recipient.Data)
+		if err != nil {
+			http.Error(w, "invalid recipient data: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		destinations[i] = sender.Destination{Email: recipient.Email, Data: string(data)}
+	}
+
+	if err := s.flusher.enqueue(req.Template, destinations); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int{"accepted": len(destinations)})
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+func (s *Server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/send", s.handleSend)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tetsuo/mailroom/emailutils/sender"
+)
+
+var (
+	sendsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mailroom_sends_total",
+		Help: "Total recipients sent, labeled by template and status.",
+	}, []string{"template", "status"})
+
+	errorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mailroom_errors_total",
+		Help: "Total recipient send errors, labeled by template.",
+	}, []string{"template"})
+)
+
+func init() {
+	prometheus.MustRegister(sendsTotal, errorsTotal)
+}
+
+// observeResult mirrors a dispatch result onto the per-template Prometheus
+// counters.
+func observeResult(template string, result sender.Result) {
+	sendsTotal.WithLabelValues(template, result.Status).Inc()
+	if result.Err != nil {
+		errorsTotal.WithLabelValues(template).Inc()
+	}
+}
@@ -0,0 +1,75 @@
+// Package server exposes mailroom's send pipeline over HTTP and gRPC, for
+// long-running deployments that want to push recipients instead of piping
+// a CSV through STDIN.
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/tetsuo/mailroom/emailutils/config"
+	"github.com/tetsuo/mailroom/emailutils/dispatch"
+	mailroomv1 "github.com/tetsuo/mailroom/emailutils/proto/mailroomv1"
+)
+
+// Server runs mailroom's HTTP and gRPC ingestion endpoints against a
+// shared, template-batching flusher.
+type Server struct {
+	cfg     *config.Config
+	flusher *flusher
+}
+
+// New builds a Server around a Dispatcher already wired to a Sender and
+// retry.Queue.
+func New(d *dispatch.Dispatcher, cfg *config.Config) *Server {
+	d.OnResult = observeResult
+	return &Server{cfg: cfg, flusher: newFlusher(cfg, d, flushInterval)}
+}
+
+// Run starts the HTTP and gRPC listeners and the batch flusher, blocking
+// until ctx is canceled or either listener fails.
+func (s *Server) Run(ctx context.Context, httpAddr, grpcAddr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		s.flusher.run(ctx)
+		return nil
+	})
+
+	httpSrv := &http.Server{Addr: httpAddr, Handler: s.httpHandler()}
+	g.Go(func() error {
+		logrus.WithField("addr", httpAddr).Info("HTTP server listening")
+		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
+
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return err
+	}
+	grpcSrv := grpc.NewServer()
+	mailroomv1.RegisterMailroomServiceServer(grpcSrv, &grpcService{flusher: s.flusher})
+	g.Go(func() error {
+		logrus.WithField("addr", grpcAddr).Info("gRPC server listening")
+		return grpcSrv.Serve(lis)
+	})
+
+	g.Go(func() error {
+		<-ctx.Done()
+		httpSrv.Shutdown(context.Background())
+		grpcSrv.GracefulStop()
+		return nil
+	})
+
+	return g.Wait()
+}